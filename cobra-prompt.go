@@ -6,19 +6,13 @@ import (
 	"os/signal"
 	"regexp"
 	"strings"
-	"syscall"
 
-	"github.com/pkg/term/termios"
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	"github.com/vikasrao23/cobra-prompt/internal/tty"
 	"github.com/vikasrao23/go-prompt"
-	"golang.org/x/sys/unix"
 )
 
-var fd int
-
-var originalTermios *unix.Termios
-
 // DynamicSuggestionsAnnotation for dynamic suggestions.
 const DynamicSuggestionsAnnotation = "cobra-prompt-dynamic-suggestions"
 
@@ -57,7 +51,7 @@ type CobraPrompt struct {
 	// AddDefaultExitCommand adds a command for exiting prompt loop
 	AddDefaultExitCommand bool
 
-	// OnErrorFunc handle error for command.Execute, if not set print error and exit
+	// OnErrorFunc handle error for command.Execute, if not set print error and continue
 	OnErrorFunc func(err error)
 
 	// InArgsParser adds a custom parser for the command line arguments (default: strings.Fields)
@@ -65,72 +59,251 @@ type CobraPrompt struct {
 
 	// SuggestionFilter will be uses when filtering suggestions as typing
 	SuggestionFilter func(suggestions []prompt.Suggest, document *prompt.Document) []prompt.Suggest
+
+	// PreExecuteHooks run, in order, before a resolved command is
+	// dispatched. A non-nil error from any hook short-circuits execution
+	// (the command is not run) and is surfaced through OnErrorFunc;
+	// remaining hooks in the list are skipped, but PostExecuteHooks still
+	// run.
+	PreExecuteHooks []func(cmd *cobra.Command, args []string) error
+
+	// PostExecuteHooks run, in order, after a command is dispatched (or
+	// skipped by a PreExecuteHooks error) regardless of outcome, so they
+	// can be used for timing, auditing, or cleanup.
+	PostExecuteHooks []func(cmd *cobra.Command, args []string, err error)
+
+	// Middleware wraps the real Cobra dispatch, composed in registration
+	// order so Middleware[0] is outermost. Use it for logging, metrics,
+	// audit trails, confirmation prompts for destructive commands, or
+	// authorization checks without forking the module.
+	Middleware []func(next ExecFunc) ExecFunc
+
+	// HistoryConfig enables persisting command history to disk across runs
+	// and Ctrl-R fuzzy reverse search. Leave nil to disable history
+	// entirely (the previous behavior).
+	HistoryConfig *HistoryConfig
+
+	// runCtx is the context passed to RunContext, and the parent of the
+	// per-command context each Executor invocation derives.
+	runCtx context.Context
+
+	term *tty.Term
+
+	hist             *history
+	historySearching bool
 }
 
 // Run will automatically generate suggestions for all cobra commands and flags defined by RootCmd
 // and execute the selected commands. Run will also reset all given flags by default, see PersistFlagValues
-func (co CobraPrompt) Run() {
-	co.RunContext(nil)
+func (co *CobraPrompt) Run() {
+	co.RunContext(context.Background())
 }
 
-// RunContext same as Run but with context
-func (co CobraPrompt) RunContext(ctx context.Context) {
+// RunContext same as Run but with context. ctx is the parent of the
+// per-command context each executed command receives; cancel it to cancel
+// a running command from outside. Ctrl-C aborts a running command without
+// killing the prompt by cancelling only that command's context (handled by
+// Executor).
+func (co *CobraPrompt) RunContext(ctx context.Context) {
 	if co.RootCmd == nil {
 		panic("RootCmd is not set. Please set RootCmd")
 	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	co.runCtx = ctx
 
 	co.prepare()
-	var err error
-	fd, err = syscall.Open("/dev/tty", syscall.O_RDONLY, 0)
+
+	term, err := tty.Open()
 	if err != nil {
 		panic(err)
 	}
-	// get the original settings
-	originalTermios, err = termios.Tcgetattr(uintptr(fd))
-	if err != nil {
+	if err := term.Save(); err != nil {
 		panic(err)
 	}
+	co.term = term
+
+	if co.HistoryConfig != nil {
+		co.hist = newHistory(*co.HistoryConfig)
+		if err := co.hist.load(); err != nil {
+			co.handleError(err)
+		}
+
+		co.GoPromptOptions = append(co.GoPromptOptions,
+			prompt.OptionHistory(co.hist.entriesSnapshot()),
+			prompt.OptionLivePrefix(func() (string, bool) {
+				if !co.historySearching {
+					return "", false
+				}
+				return "(reverse-i-search): ", true
+			}),
+			prompt.OptionAddKeyBind(prompt.KeyBind{
+				Key: prompt.ControlR,
+				Fn:  co.startHistorySearch,
+			}),
+		)
+	}
 
 	p := prompt.New(
-		Executor, completer,
+		co.Executor, co.completer,
 		co.GoPromptOptions...,
 	)
 
 	p.Run()
 }
 
-func Executor(input string) {
-	// restore the original settings to allow ctrl-c to generate signal
-	if err := termios.Tcsetattr(uintptr(fd), termios.TCSANOW, (*unix.Termios)(originalTermios)); err != nil {
-		panic(err)
+// startHistorySearch is bound to Ctrl-R: it clears the buffer and switches
+// completer into fuzzy history-search mode. Typing then filters history by
+// subsequence match; selecting a match puts it in the buffer, and Enter
+// runs whatever the buffer holds via Executor (the typed query itself when
+// no match was selected).
+func (co *CobraPrompt) startHistorySearch(buf *prompt.Buffer) {
+	if co.hist == nil {
+		return
 	}
+	co.historySearching = true
+	if t := buf.Text(); t != "" {
+		buf.DeleteBeforeCursor(len([]rune(t)))
+	}
+}
 
-	if input == "test" {
-		ctx, cancel := context.WithCancel(context.Background())
-		c := make(chan os.Signal, 1)
-		signal.Notify(c, os.Interrupt)
+// Executor parses input with parseArgs and dispatches it to RootCmd.
+// SIGINT cancels the command's context so long-running subcommands can be
+// aborted with Ctrl-C without killing the prompt, non-persisted flags are
+// reset to their defaults once the command returns, and input is appended
+// to history once the command completes without error. When input was
+// selected from Ctrl-R's fuzzy search, it's already the full matched
+// history entry (go-prompt inserted it into the buffer on selection), so
+// it's used as-is rather than re-derived.
+func (co *CobraPrompt) Executor(input string) {
+	co.historySearching = false
+
+	args := co.parseArgs(input)
+	if len(args) == 0 {
+		return
+	}
 
+	// go-prompt puts the tty in raw mode while reading input, which masks
+	// ctrl-c as a regular keystroke. Leave cooked mode for the duration of
+	// the command so ctrl-c generates a real SIGINT, then go back to
+	// go-prompt's mode before returning control to it.
+	err := co.term.WithCookedMode(func() error {
+		ctx, cancel := context.WithCancel(co.runCtx)
+		defer cancel()
+
+		sig := make(chan os.Signal, 1)
+		signal.Notify(sig, os.Interrupt)
+		defer signal.Stop(sig)
 		go func() {
 			select {
-			case <-c:
+			case <-sig:
 				cancel()
+			case <-ctx.Done():
 			}
 		}()
-		go func() {
-			defer cancel()
-			for { // long task
+
+		return co.dispatch(ctx, args)
+	})
+	if err != nil {
+		co.handleError(err)
+		return
+	}
+
+	co.recordHistory(input)
+}
+
+// ExecFunc runs a resolved command with its remaining args. It's the
+// shape both the real Cobra dispatch and every entry in Middleware take,
+// so middleware can wrap the dispatcher or each other.
+type ExecFunc func(cmd *cobra.Command, args []string) error
+
+// dispatch resolves args to a command, then runs, in order: PreExecuteHooks,
+// the Middleware-wrapped Cobra dispatch, and PostExecuteHooks. A
+// PreExecuteHook error short-circuits the dispatch itself but
+// PostExecuteHooks still run, receiving that error. The innermost ExecFunc
+// runs the full original args through RootCmd; the cmd and args passed to
+// it are not used, so a middleware that changes them doesn't change what
+// runs.
+func (co *CobraPrompt) dispatch(ctx context.Context, args []string) error {
+	cmd, cmdArgs, findErr := co.RootCmd.Find(args)
+	if findErr != nil {
+		cmd, cmdArgs = co.RootCmd, args
+	}
+
+	exec := ExecFunc(func(cmd *cobra.Command, _ []string) error {
+		co.RootCmd.SetArgs(args)
+		executed, err := co.RootCmd.ExecuteContextC(ctx)
+		co.resetFlags(executed)
+		return err
+	})
+	for i := len(co.Middleware) - 1; i >= 0; i-- {
+		exec = co.Middleware[i](exec)
+	}
+
+	var err error
+	for _, hook := range co.PreExecuteHooks {
+		if err = hook(cmd, cmdArgs); err != nil {
+			break
+		}
+	}
+	if err == nil {
+		err = exec(cmd, cmdArgs)
+	}
+
+	for _, hook := range co.PostExecuteHooks {
+		hook(cmd, cmdArgs, err)
+	}
+
+	return err
+}
+
+// handleError routes a command error through OnErrorFunc, defaulting to
+// printing it and continuing rather than exiting the prompt.
+func (co *CobraPrompt) handleError(err error) {
+	if co.OnErrorFunc != nil {
+		co.OnErrorFunc(err)
+		return
+	}
+	co.RootCmd.PrintErrln(err)
+}
+
+// resetFlags resets every changed flag in RootCmd's command tree back to
+// its default value, unless PersistFlagValuesFlag was set on the executed
+// command, mirroring the reset findSuggestions does for whichever command
+// is currently highlighted.
+func (co *CobraPrompt) resetFlags(executed *cobra.Command) {
+	if executed == nil {
+		return
+	}
+	if persist, _ := executed.Flags().GetBool(PersistFlagValuesFlag); persist {
+		return
+	}
+
+	var reset func(c *cobra.Command)
+	reset = func(c *cobra.Command) {
+		c.Flags().VisitAll(func(flag *pflag.Flag) {
+			if flag.Changed {
+				flag.Value.Set(flag.DefValue)
 			}
-		}()
-		select {
-		case <-ctx.Done():
-			return
+		})
+		for _, sub := range c.Commands() {
+			reset(sub)
 		}
 	}
+	reset(co.RootCmd)
 }
 
-func completer(d prompt.Document) []prompt.Suggest {
-	s := []prompt.Suggest{}
-	return prompt.FilterHasPrefix(s, d.GetWordBeforeCursor(), true)
+func (co *CobraPrompt) completer(d prompt.Document) []prompt.Suggest {
+	if co.historySearching && co.hist != nil {
+		return historySuggestions(co.hist.fuzzySearch(d.Text))
+	}
+
+	suggestions := findSuggestions(co, &d)
+	if co.hist != nil && co.HistoryConfig.RankSuggestions && strings.TrimSpace(d.TextBeforeCursor()) == "" {
+		suggestions = rankSuggestions(suggestions, co.hist.entriesSnapshot())
+	}
+	return suggestions
 }
 
 func parseArgsWithQuotes(input string) []string {
@@ -149,7 +322,7 @@ func parseArgsWithQuotes(input string) []string {
 	return args
 }
 
-func (co CobraPrompt) parseArgs(in string) []string {
+func (co *CobraPrompt) parseArgs(in string) []string {
 	if co.InArgsParser != nil {
 		return co.InArgsParser(in)
 	}
@@ -157,7 +330,13 @@ func (co CobraPrompt) parseArgs(in string) []string {
 	return parseArgsWithQuotes(in)
 }
 
-func (co CobraPrompt) prepare() {
+func (co *CobraPrompt) prepare() {
+	// Errors are routed through handleError/OnErrorFunc instead, so
+	// suppress Cobra's own default printing of them (and of usage on
+	// error); otherwise every failed command's error prints twice.
+	co.RootCmd.SilenceErrors = true
+	co.RootCmd.SilenceUsage = true
+
 	if co.ShowHelpCommandAndFlags {
 		// TODO: Add suggestions for help command
 		co.RootCmd.InitDefaultHelpCmd()
@@ -187,12 +366,28 @@ func findSuggestions(co *CobraPrompt, d *prompt.Document) []prompt.Suggest {
 	command := co.RootCmd
 	args := strings.Fields(d.CurrentLine())
 
-	if found, _, err := command.Find(args); err == nil {
+	var cmdArgs []string
+	if found, rest, err := command.Find(args); err == nil {
 		command = found
+		cmdArgs = rest
+	}
+
+	toComplete := d.GetWordBeforeCursor()
+	shellSuggestions, directive, isFlagValue, handled := shellCompSuggestions(command, cmdArgs, toComplete)
+	if handled && directive&cobra.ShellCompDirectiveError != 0 {
+		return nil
+	}
+	if handled && isFlagValue {
+		if co.SuggestionFilter != nil {
+			return co.SuggestionFilter(shellSuggestions, d)
+		}
+		return shellSuggestions
 	}
 
 	var suggestions []prompt.Suggest
 	persistFlagValues, _ := command.Flags().GetBool(PersistFlagValuesFlag)
+	present := flagsOnLine(command.Flags(), cmdArgs, toComplete)
+	boosted := map[string]bool{}
 	addFlags := func(flag *pflag.Flag) {
 		if flag.Changed && !persistFlagValues {
 			flag.Value.Set(flag.DefValue)
@@ -200,15 +395,24 @@ func findSuggestions(co *CobraPrompt, d *prompt.Document) []prompt.Suggest {
 		if flag.Hidden && !co.ShowHiddenFlags {
 			return
 		}
-		if strings.HasPrefix(d.GetWordBeforeCursor(), "--") {
-			suggestions = append(suggestions, prompt.Suggest{Text: "--" + flag.Name, Description: flag.Usage})
-		} else if strings.HasPrefix(d.GetWordBeforeCursor(), "-") && flag.Shorthand != "" {
-			suggestions = append(suggestions, prompt.Suggest{Text: "-" + flag.Shorthand, Description: flag.Usage})
+		if hiddenByMutualExclusion(flag, present) {
+			return
+		}
+		if boostedByGroup(flag, present) {
+			boosted[flag.Name] = true
+		}
+
+		usage := groupDescription(flag.Usage, flag)
+		if strings.HasPrefix(toComplete, "--") || (toComplete == "" && boosted[flag.Name]) {
+			suggestions = append(suggestions, prompt.Suggest{Text: "--" + flag.Name, Description: usage})
+		} else if strings.HasPrefix(toComplete, "-") && flag.Shorthand != "" {
+			suggestions = append(suggestions, prompt.Suggest{Text: "-" + flag.Shorthand, Description: usage})
 		}
 	}
 
 	command.LocalFlags().VisitAll(addFlags)
 	command.InheritedFlags().VisitAll(addFlags)
+	suggestions = prioritizeBoosted(suggestions, boosted)
 
 	if command.HasAvailableSubCommands() {
 		for _, c := range command.Commands() {
@@ -221,6 +425,10 @@ func findSuggestions(co *CobraPrompt, d *prompt.Document) []prompt.Suggest {
 		}
 	}
 
+	if handled {
+		suggestions = append(suggestions, shellSuggestions...)
+	}
+
 	annotation := command.Annotations[DynamicSuggestionsAnnotation]
 	if co.DynamicSuggestionsFunc != nil && annotation != "" {
 		suggestions = append(suggestions, co.DynamicSuggestionsFunc(annotation, d)...)
@@ -0,0 +1,99 @@
+package cobraprompt
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func newDispatchCmd(runErr error) *cobra.Command {
+	root := &cobra.Command{Use: "root"}
+	root.AddCommand(&cobra.Command{
+		Use: "fail",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runErr
+		},
+	})
+	root.SilenceErrors = true
+	root.SilenceUsage = true
+	return root
+}
+
+func TestDispatchSurfacesCommandError(t *testing.T) {
+	wantErr := errors.New("boom")
+	co := &CobraPrompt{RootCmd: newDispatchCmd(wantErr)}
+
+	err := co.dispatch(context.Background(), []string{"fail"})
+	if !errors.Is(err, wantErr) {
+		t.Errorf("dispatch error = %v, want %v", err, wantErr)
+	}
+}
+
+func TestDispatchPreExecuteHookShortCircuits(t *testing.T) {
+	hookErr := errors.New("denied")
+	ran := false
+	root := newDispatchCmd(nil)
+	root.Commands()[0].RunE = func(cmd *cobra.Command, args []string) error {
+		ran = true
+		return nil
+	}
+
+	co := &CobraPrompt{
+		RootCmd: root,
+		PreExecuteHooks: []func(cmd *cobra.Command, args []string) error{
+			func(cmd *cobra.Command, args []string) error { return hookErr },
+		},
+	}
+
+	err := co.dispatch(context.Background(), []string{"fail"})
+	if !errors.Is(err, hookErr) {
+		t.Errorf("dispatch error = %v, want %v", err, hookErr)
+	}
+	if ran {
+		t.Error("command ran despite PreExecuteHooks error")
+	}
+}
+
+func TestDispatchPostExecuteHookAlwaysRuns(t *testing.T) {
+	wantErr := errors.New("boom")
+	var gotErr error
+	co := &CobraPrompt{
+		RootCmd: newDispatchCmd(wantErr),
+		PostExecuteHooks: []func(cmd *cobra.Command, args []string, err error){
+			func(cmd *cobra.Command, args []string, err error) { gotErr = err },
+		},
+	}
+
+	_ = co.dispatch(context.Background(), []string{"fail"})
+	if !errors.Is(gotErr, wantErr) {
+		t.Errorf("PostExecuteHooks saw err = %v, want %v", gotErr, wantErr)
+	}
+}
+
+func TestResetFlags(t *testing.T) {
+	root := &cobra.Command{Use: "root"}
+	root.Flags().String("name", "default", "")
+	root.Flags().Bool(PersistFlagValuesFlag, false, "")
+
+	co := &CobraPrompt{RootCmd: root}
+
+	root.Flags().Set("name", "changed")
+	co.resetFlags(root)
+	if got, _ := root.Flags().GetString("name"); got != "default" {
+		t.Errorf("name = %q, want reset to default", got)
+	}
+
+	root.Flags().Set("name", "changed-again")
+	root.Flags().Set(PersistFlagValuesFlag, "true")
+	co.resetFlags(root)
+	if got, _ := root.Flags().GetString("name"); got != "changed-again" {
+		t.Errorf("name = %q, want persisted (not reset)", got)
+	}
+}
+
+func TestResetFlagsNilExecuted(t *testing.T) {
+	co := &CobraPrompt{RootCmd: &cobra.Command{Use: "root"}}
+	co.resetFlags(nil) // must not panic
+}
@@ -0,0 +1,150 @@
+package cobraprompt
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/pflag"
+	"github.com/vikasrao23/go-prompt"
+)
+
+// Flag annotation keys Cobra uses to record MarkFlagsRequiredTogether,
+// MarkFlagsMutuallyExclusive, and MarkFlagsOneRequired groups. Cobra does
+// not export these as constants, so the literal values are duplicated here.
+const (
+	requiredTogetherAnnotation  = "cobra_annotation_required_if_others_set"
+	mutuallyExclusiveAnnotation = "cobra_annotation_mutually_exclusive"
+	oneRequiredAnnotation       = "cobra_annotation_one_required"
+)
+
+// flagGroup looks up the sibling flag names for one of the group
+// annotations above, excluding name itself. Cobra stores each group as one
+// annotation entry per MarkFlags* call, with the group's flag names joined
+// by spaces in a single string, so each entry needs splitting before use.
+func flagGroup(flag *pflag.Flag, annotation string) []string {
+	group, ok := flag.Annotations[annotation]
+	if !ok {
+		return nil
+	}
+
+	siblings := make([]string, 0, len(group))
+	for _, entry := range group {
+		for _, name := range strings.Fields(entry) {
+			if name != flag.Name {
+				siblings = append(siblings, name)
+			}
+		}
+	}
+	return siblings
+}
+
+// flagsOnLine returns the set of resolved (long-form) flag names already
+// typed on the current line, excluding the word currently under the
+// cursor.
+func flagsOnLine(flags *pflag.FlagSet, cmdArgs []string, toComplete string) map[string]bool {
+	args := cmdArgs
+	if len(args) > 0 && toComplete != "" && args[len(args)-1] == toComplete {
+		args = args[:len(args)-1]
+	}
+
+	present := map[string]bool{}
+	for _, a := range args {
+		if !strings.HasPrefix(a, "-") {
+			continue
+		}
+
+		name := strings.TrimLeft(a, "-")
+		if idx := strings.IndexByte(name, '='); idx >= 0 {
+			name = name[:idx]
+		}
+		if !strings.HasPrefix(a, "--") && len(name) == 1 && flags != nil {
+			if f := flags.ShorthandLookup(name); f != nil {
+				name = f.Name
+			}
+		}
+		present[name] = true
+	}
+	return present
+}
+
+// groupDescription appends a hint to a flag's usage describing the flag
+// group(s) it belongs to, e.g. "[requires --b, --c]" or "[exclusive with
+// --x]", so users understand why a flag was surfaced or omitted.
+func groupDescription(usage string, flag *pflag.Flag) string {
+	var hints []string
+	if siblings := flagGroup(flag, requiredTogetherAnnotation); len(siblings) > 0 {
+		hints = append(hints, fmt.Sprintf("requires --%s", strings.Join(siblings, ", --")))
+	}
+	if siblings := flagGroup(flag, mutuallyExclusiveAnnotation); len(siblings) > 0 {
+		hints = append(hints, fmt.Sprintf("exclusive with --%s", strings.Join(siblings, ", --")))
+	}
+	if siblings := flagGroup(flag, oneRequiredAnnotation); len(siblings) > 0 {
+		hints = append(hints, fmt.Sprintf("one of --%s, --%s required", flag.Name, strings.Join(siblings, ", --")))
+	}
+
+	if len(hints) == 0 {
+		return usage
+	}
+	return fmt.Sprintf("%s [%s]", usage, strings.Join(hints, "; "))
+}
+
+// hiddenByMutualExclusion reports whether flag should be hidden from
+// suggestions because a flag it's mutually exclusive with is already
+// present on the line.
+func hiddenByMutualExclusion(flag *pflag.Flag, present map[string]bool) bool {
+	for _, sibling := range flagGroup(flag, mutuallyExclusiveAnnotation) {
+		if present[sibling] {
+			return true
+		}
+	}
+	return false
+}
+
+// boostedByGroup reports whether flag should be surfaced even without a
+// "-"/"--" prefix typed yet: either it's a member of a one-required group
+// that's still unsatisfied, or it's the unset sibling of a
+// required-together group whose first member was already typed.
+func boostedByGroup(flag *pflag.Flag, present map[string]bool) bool {
+	if present[flag.Name] {
+		return false
+	}
+
+	if siblings := flagGroup(flag, oneRequiredAnnotation); len(siblings) > 0 {
+		satisfied := present[flag.Name]
+		for _, sibling := range siblings {
+			satisfied = satisfied || present[sibling]
+		}
+		if !satisfied {
+			return true
+		}
+	}
+
+	for _, sibling := range flagGroup(flag, requiredTogetherAnnotation) {
+		if present[sibling] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// prioritizeBoosted moves suggestions for boosted flags (see
+// boostedByGroup) to the front of the list so they stand out: members of an
+// unsatisfied one-required group, and unset siblings of a required-together
+// group once one member has been typed.
+func prioritizeBoosted(suggestions []prompt.Suggest, boosted map[string]bool) []prompt.Suggest {
+	if len(boosted) == 0 {
+		return suggestions
+	}
+
+	front := make([]prompt.Suggest, 0, len(suggestions))
+	back := make([]prompt.Suggest, 0, len(suggestions))
+	for _, s := range suggestions {
+		if boosted[strings.TrimLeft(s.Text, "-")] {
+			front = append(front, s)
+		} else {
+			back = append(back, s)
+		}
+	}
+	return append(front, back...)
+}
@@ -0,0 +1,86 @@
+package cobraprompt
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func newGroupedCmd() *cobra.Command {
+	cmd := &cobra.Command{Use: "root"}
+	cmd.Flags().String("a", "", "a flag")
+	cmd.Flags().String("b", "", "b flag")
+	cmd.Flags().String("c", "", "c flag")
+	cmd.Flags().String("x", "", "x flag")
+	cmd.Flags().String("y", "", "y flag")
+	cmd.MarkFlagsRequiredTogether("a", "b")
+	cmd.MarkFlagsMutuallyExclusive("x", "y")
+	cmd.MarkFlagsOneRequired("a", "c")
+	return cmd
+}
+
+func sorted(s []string) []string {
+	out := append([]string(nil), s...)
+	sort.Strings(out)
+	return out
+}
+
+func TestFlagGroupRealCobraAnnotations(t *testing.T) {
+	cmd := newGroupedCmd()
+
+	tests := []struct {
+		name       string
+		flag       string
+		annotation string
+		want       []string
+	}{
+		{"required-together sibling", "a", requiredTogetherAnnotation, []string{"b"}},
+		{"required-together sibling reverse", "b", requiredTogetherAnnotation, []string{"a"}},
+		{"mutually-exclusive sibling", "x", mutuallyExclusiveAnnotation, []string{"y"}},
+		{"one-required sibling", "a", oneRequiredAnnotation, []string{"c"}},
+		{"no group membership", "b", mutuallyExclusiveAnnotation, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			flag := cmd.Flags().Lookup(tt.flag)
+			got := flagGroup(flag, tt.annotation)
+			if !reflect.DeepEqual(sorted(got), sorted(tt.want)) {
+				t.Errorf("flagGroup(%s, %s) = %v, want %v", tt.flag, tt.annotation, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHiddenByMutualExclusion(t *testing.T) {
+	cmd := newGroupedCmd()
+	x := cmd.Flags().Lookup("x")
+
+	if hiddenByMutualExclusion(x, map[string]bool{}) {
+		t.Error("x should not be hidden when y isn't present")
+	}
+	if !hiddenByMutualExclusion(x, map[string]bool{"y": true}) {
+		t.Error("x should be hidden when mutually-exclusive sibling y is present")
+	}
+}
+
+func TestBoostedByGroup(t *testing.T) {
+	cmd := newGroupedCmd()
+	b := cmd.Flags().Lookup("b")
+	c := cmd.Flags().Lookup("c")
+
+	if boostedByGroup(b, map[string]bool{}) {
+		t.Error("b should not be boosted before its required-together sibling a is typed")
+	}
+	if !boostedByGroup(b, map[string]bool{"a": true}) {
+		t.Error("b should be boosted once required-together sibling a is present")
+	}
+	if !boostedByGroup(c, map[string]bool{}) {
+		t.Error("c should be boosted while its one-required group is unsatisfied")
+	}
+	if boostedByGroup(c, map[string]bool{"a": true}) {
+		t.Error("c should not be boosted once its one-required group is satisfied by a")
+	}
+}
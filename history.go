@@ -0,0 +1,234 @@
+package cobraprompt
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/vikasrao23/go-prompt"
+)
+
+// DedupPolicy controls how duplicate command lines are handled when
+// appending to persisted history.
+type DedupPolicy int
+
+const (
+	// DedupNone keeps every entry, even consecutive duplicates.
+	DedupNone DedupPolicy = iota
+	// DedupConsecutive drops an entry identical to the immediately
+	// preceding one.
+	DedupConsecutive
+	// DedupAll keeps only the most recent occurrence of any entry,
+	// moving it to the end of the history.
+	DedupAll
+)
+
+// HistoryConfig configures persistent command history for a CobraPrompt.
+// See CobraPrompt.HistoryConfig.
+type HistoryConfig struct {
+	// Path is the file history is loaded from and appended to.
+	Path string
+
+	// MaxEntries caps how many entries are kept on disk and in memory;
+	// zero means unlimited.
+	MaxEntries int
+
+	// DedupPolicy controls how duplicate entries are collapsed on append.
+	DedupPolicy DedupPolicy
+
+	// Redact, when set, is applied to the parsed args of every executed
+	// command before it's written to disk, letting integrators strip
+	// sensitive values (e.g. the value following --password) from what
+	// gets persisted.
+	Redact func(args []string) []string
+
+	// RankSuggestions, when true, ranks top-level command suggestions by
+	// recency/frequency in history when the user hasn't typed anything
+	// yet.
+	RankSuggestions bool
+}
+
+// history is the in-memory, disk-backed command history for a running
+// CobraPrompt.
+type history struct {
+	mu      sync.Mutex
+	cfg     HistoryConfig
+	entries []string
+}
+
+func newHistory(cfg HistoryConfig) *history {
+	return &history{cfg: cfg}
+}
+
+// load reads existing history entries from cfg.Path, oldest first. A
+// missing file is not an error; history just starts empty.
+func (h *history) load() error {
+	if h.cfg.Path == "" {
+		return nil
+	}
+
+	f, err := os.Open(h.cfg.Path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.entries = h.entries[:0]
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if line := scanner.Text(); line != "" {
+			h.entries = append(h.entries, line)
+		}
+	}
+	if h.cfg.MaxEntries > 0 && len(h.entries) > h.cfg.MaxEntries {
+		h.entries = h.entries[len(h.entries)-h.cfg.MaxEntries:]
+	}
+	return scanner.Err()
+}
+
+// append records line to memory and disk, applying cfg.DedupPolicy and
+// cfg.MaxEntries. line should already have been through cfg.Redact.
+func (h *history) append(line string) error {
+	if line == "" {
+		return nil
+	}
+
+	h.mu.Lock()
+	switch h.cfg.DedupPolicy {
+	case DedupConsecutive:
+		if len(h.entries) > 0 && h.entries[len(h.entries)-1] == line {
+			h.mu.Unlock()
+			return nil
+		}
+	case DedupAll:
+		for i, e := range h.entries {
+			if e == line {
+				h.entries = append(h.entries[:i], h.entries[i+1:]...)
+				break
+			}
+		}
+	}
+
+	h.entries = append(h.entries, line)
+	if h.cfg.MaxEntries > 0 && len(h.entries) > h.cfg.MaxEntries {
+		h.entries = h.entries[len(h.entries)-h.cfg.MaxEntries:]
+	}
+	entries := append([]string(nil), h.entries...)
+	h.mu.Unlock()
+
+	if h.cfg.Path == "" {
+		return nil
+	}
+	return h.flush(entries)
+}
+
+// flush rewrites cfg.Path with entries, one per line.
+func (h *history) flush(entries []string) error {
+	if dir := filepath.Dir(h.cfg.Path); dir != "." {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return err
+		}
+	}
+
+	f, err := os.Create(h.cfg.Path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	for _, e := range entries {
+		if _, err := w.WriteString(e + "\n"); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
+
+// entriesSnapshot returns a copy of the current history, oldest first.
+func (h *history) entriesSnapshot() []string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return append([]string(nil), h.entries...)
+}
+
+// fuzzySearch returns history entries, most recent first, whose text
+// contains query as an ordered subsequence.
+func (h *history) fuzzySearch(query string) []string {
+	entries := h.entriesSnapshot()
+
+	var matches []string
+	for i := len(entries) - 1; i >= 0; i-- {
+		if fuzzyMatch(entries[i], query) {
+			matches = append(matches, entries[i])
+		}
+	}
+	return matches
+}
+
+// fuzzyMatch reports whether query appears in text as a (possibly
+// non-contiguous) ordered subsequence, case-insensitively.
+func fuzzyMatch(text, query string) bool {
+	text, query = strings.ToLower(text), strings.ToLower(query)
+	qi := 0
+	for i := 0; i < len(text) && qi < len(query); i++ {
+		if text[i] == query[qi] {
+			qi++
+		}
+	}
+	return qi == len(query)
+}
+
+// historySuggestions wraps fuzzySearch matches as prompt suggestions for
+// the Ctrl-R reverse search completer.
+func historySuggestions(matches []string) []prompt.Suggest {
+	suggestions := make([]prompt.Suggest, 0, len(matches))
+	for _, m := range matches {
+		suggestions = append(suggestions, prompt.Suggest{Text: m})
+	}
+	return suggestions
+}
+
+// rankSuggestions reorders suggestions (stably) so ones whose Text matches
+// the first word of a history entry float to the top, most-recently/most-
+// frequently used first.
+func rankSuggestions(suggestions []prompt.Suggest, entries []string) []prompt.Suggest {
+	score := make(map[string]int, len(entries))
+	for rank, e := range entries {
+		fields := strings.Fields(e)
+		if len(fields) == 0 {
+			continue
+		}
+		score[fields[0]] += rank + 1
+	}
+
+	ranked := append([]prompt.Suggest(nil), suggestions...)
+	sort.SliceStable(ranked, func(i, j int) bool {
+		return score[ranked[i].Text] > score[ranked[j].Text]
+	})
+	return ranked
+}
+
+// recordHistory redacts (if configured) and persists line to co's history.
+// It's best-effort: a write failure shouldn't break the prompt.
+func (co *CobraPrompt) recordHistory(line string) {
+	if co.hist == nil {
+		return
+	}
+
+	toWrite := line
+	if co.hist.cfg.Redact != nil {
+		toWrite = strings.Join(co.hist.cfg.Redact(co.parseArgs(line)), " ")
+	}
+
+	_ = co.hist.append(toWrite)
+}
@@ -0,0 +1,80 @@
+package cobraprompt
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestFuzzyMatch(t *testing.T) {
+	tests := []struct {
+		text, query string
+		want        bool
+	}{
+		{"git commit -m foo", "gcm", true},
+		{"git commit -m foo", "GCM", true},
+		{"git commit -m foo", "mgc", false},
+		{"git commit -m foo", "", true},
+		{"", "x", false},
+	}
+
+	for _, tt := range tests {
+		if got := fuzzyMatch(tt.text, tt.query); got != tt.want {
+			t.Errorf("fuzzyMatch(%q, %q) = %v, want %v", tt.text, tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestHistoryAppendDedupPolicies(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy DedupPolicy
+		lines  []string
+		want   []string
+	}{
+		{
+			name:   "none keeps consecutive duplicates",
+			policy: DedupNone,
+			lines:  []string{"a", "a", "b"},
+			want:   []string{"a", "a", "b"},
+		},
+		{
+			name:   "consecutive drops only immediate repeats",
+			policy: DedupConsecutive,
+			lines:  []string{"a", "a", "b", "a"},
+			want:   []string{"a", "b", "a"},
+		},
+		{
+			name:   "all moves repeats to the end",
+			policy: DedupAll,
+			lines:  []string{"a", "b", "a"},
+			want:   []string{"b", "a"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := newHistory(HistoryConfig{DedupPolicy: tt.policy})
+			for _, line := range tt.lines {
+				if err := h.append(line); err != nil {
+					t.Fatalf("append(%q) error: %v", line, err)
+				}
+			}
+			if got := h.entriesSnapshot(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("entries = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryAppendMaxEntries(t *testing.T) {
+	h := newHistory(HistoryConfig{MaxEntries: 2})
+	for _, line := range []string{"a", "b", "c"} {
+		if err := h.append(line); err != nil {
+			t.Fatalf("append(%q) error: %v", line, err)
+		}
+	}
+	want := []string{"b", "c"}
+	if got := h.entriesSnapshot(); !reflect.DeepEqual(got, want) {
+		t.Errorf("entries = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,26 @@
+//go:build plan9
+
+// Package tty provides minimal cross-platform terminal mode control.
+// Plan 9 consoles have no termios-style mode to save or restore, so every
+// operation here is a no-op and WithCookedMode just runs fn.
+package tty
+
+// Term is a no-op terminal mode controller on plan9.
+type Term struct{}
+
+// Open returns a no-op Term; there's nothing to open on plan9.
+func Open() (*Term, error) {
+	return &Term{}, nil
+}
+
+// Save is a no-op on plan9.
+func (t *Term) Save() error { return nil }
+
+// Restore is a no-op on plan9.
+func (t *Term) Restore() error { return nil }
+
+// WithCookedMode just runs fn; plan9 has no raw/cooked mode distinction to
+// toggle.
+func (t *Term) WithCookedMode(fn func() error) error {
+	return fn()
+}
@@ -0,0 +1,83 @@
+//go:build !windows && !plan9
+
+// Package tty provides minimal cross-platform terminal mode control: save
+// the current mode, restore it later, or temporarily run a function in
+// cooked mode. cobra-prompt uses it to leave go-prompt's raw mode while a
+// subcommand runs, so ctrl-c reaches it as a normal signal instead of a
+// raw keystroke.
+package tty
+
+import (
+	"errors"
+	"os"
+
+	"github.com/pkg/term/termios"
+	"golang.org/x/sys/unix"
+)
+
+// Term controls the mode of a single terminal file descriptor.
+type Term struct {
+	f     *os.File // kept so its finalizer doesn't close fd
+	fd    int
+	saved unix.Termios
+	ok    bool // false when fd isn't a real tty (ENOTTY); every op becomes a no-op
+}
+
+// Open opens /dev/tty for mode control.
+func Open() (*Term, error) {
+	f, err := os.OpenFile("/dev/tty", os.O_RDONLY, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &Term{f: f, fd: int(f.Fd())}, nil
+}
+
+// Save records the terminal's current mode so a later Restore can return
+// to it. A descriptor that isn't a real terminal (ENOTTY) is treated as a
+// permanent no-op rather than an error, so the prompt still runs. The
+// descriptor is /dev/tty rather than stdin, so piping stdin alone doesn't
+// lead here; with no controlling terminal at all, Open fails instead.
+func (t *Term) Save() error {
+	attr, err := termios.Tcgetattr(uintptr(t.fd))
+	if err != nil {
+		if errors.Is(err, unix.ENOTTY) {
+			t.ok = false
+			return nil
+		}
+		return err
+	}
+	t.saved = *attr
+	t.ok = true
+	return nil
+}
+
+// Restore reapplies the mode last captured by Save.
+func (t *Term) Restore() error {
+	if !t.ok {
+		return nil
+	}
+	return termios.Tcsetattr(uintptr(t.fd), termios.TCSANOW, &t.saved)
+}
+
+// WithCookedMode temporarily switches to cooked mode (echo, line editing,
+// and signal generation enabled), runs fn, then restores whatever mode was
+// active before the call.
+func (t *Term) WithCookedMode(fn func() error) error {
+	if !t.ok {
+		return fn()
+	}
+
+	before, err := termios.Tcgetattr(uintptr(t.fd))
+	if err != nil {
+		return err
+	}
+
+	cooked := *before
+	cooked.Lflag |= unix.ISIG | unix.ICANON | unix.ECHO
+	if err := termios.Tcsetattr(uintptr(t.fd), termios.TCSANOW, &cooked); err != nil {
+		return err
+	}
+	defer termios.Tcsetattr(uintptr(t.fd), termios.TCSANOW, before)
+
+	return fn()
+}
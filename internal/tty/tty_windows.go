@@ -0,0 +1,89 @@
+//go:build windows
+
+// Package tty provides minimal cross-platform terminal mode control: save
+// the current mode, restore it later, or temporarily run a function in
+// cooked mode. cobra-prompt uses it to leave go-prompt's raw mode while a
+// subcommand runs, so ctrl-c reaches it normally instead of as a raw
+// keystroke.
+package tty
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// Term controls the console mode of a console's input and output handles.
+// Input-mode flags (line input, echo, processed input) only take effect on
+// the input handle; ENABLE_VIRTUAL_TERMINAL_PROCESSING is an output-mode
+// flag and only takes effect on the output handle.
+type Term struct {
+	files    [2]*os.File // kept so their finalizers don't close in and out
+	in       windows.Handle
+	out      windows.Handle
+	savedIn  uint32
+	savedOut uint32
+}
+
+// Open opens the active console's input and output for mode control.
+func Open() (*Term, error) {
+	in, err := os.OpenFile("CONIN$", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	out, err := os.OpenFile("CONOUT$", os.O_RDWR, 0)
+	if err != nil {
+		return nil, err
+	}
+	return &Term{
+		files: [2]*os.File{in, out},
+		in:    windows.Handle(in.Fd()),
+		out:   windows.Handle(out.Fd()),
+	}, nil
+}
+
+// Save records the console's current input and output modes so a later
+// Restore can return to them.
+func (t *Term) Save() error {
+	if err := windows.GetConsoleMode(t.in, &t.savedIn); err != nil {
+		return err
+	}
+	return windows.GetConsoleMode(t.out, &t.savedOut)
+}
+
+// Restore reapplies the modes last captured by Save.
+func (t *Term) Restore() error {
+	if err := windows.SetConsoleMode(t.in, t.savedIn); err != nil {
+		return err
+	}
+	return windows.SetConsoleMode(t.out, t.savedOut)
+}
+
+// WithCookedMode temporarily enables line input, echo, and processed input
+// on the input handle (plus virtual terminal processing on the output
+// handle, so ANSI sequences keep working), runs fn, then restores whatever
+// modes were active before the call.
+func (t *Term) WithCookedMode(fn func() error) error {
+	var beforeIn, beforeOut uint32
+	if err := windows.GetConsoleMode(t.in, &beforeIn); err != nil {
+		return err
+	}
+	if err := windows.GetConsoleMode(t.out, &beforeOut); err != nil {
+		return err
+	}
+
+	cookedIn := beforeIn | windows.ENABLE_LINE_INPUT | windows.ENABLE_ECHO_INPUT |
+		windows.ENABLE_PROCESSED_INPUT
+	if err := windows.SetConsoleMode(t.in, cookedIn); err != nil {
+		return err
+	}
+	defer windows.SetConsoleMode(t.in, beforeIn)
+
+	cookedOut := beforeOut | windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING
+	if err := windows.SetConsoleMode(t.out, cookedOut); err != nil {
+		return err
+	}
+	defer windows.SetConsoleMode(t.out, beforeOut)
+
+	return fn()
+}
@@ -0,0 +1,42 @@
+package cobraprompt
+
+import (
+	"context"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestDispatchMiddlewareOrdering(t *testing.T) {
+	var order []string
+	mw := func(name string) func(next ExecFunc) ExecFunc {
+		return func(next ExecFunc) ExecFunc {
+			return func(cmd *cobra.Command, args []string) error {
+				order = append(order, name+":before")
+				err := next(cmd, args)
+				order = append(order, name+":after")
+				return err
+			}
+		}
+	}
+
+	co := &CobraPrompt{
+		RootCmd:    newDispatchCmd(nil),
+		Middleware: []func(next ExecFunc) ExecFunc{mw("outer"), mw("inner")},
+	}
+
+	if err := co.dispatch(context.Background(), []string{"fail"}); err != nil {
+		t.Fatalf("dispatch error: %v", err)
+	}
+
+	want := []string{"outer:before", "inner:before", "inner:after", "outer:after"}
+	if len(order) != len(want) {
+		t.Fatalf("order = %v, want %v", order, want)
+	}
+	for i := range want {
+		if order[i] != want[i] {
+			t.Errorf("order = %v, want %v", order, want)
+			break
+		}
+	}
+}
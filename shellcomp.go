@@ -0,0 +1,201 @@
+package cobraprompt
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/vikasrao23/go-prompt"
+)
+
+// shellCompSuggestions bridges Cobra's native shell-completion machinery
+// (ValidArgsFunction and RegisterFlagCompletionFunc) into prompt.Suggest
+// entries, honoring the ShellCompDirective bitmask the completion func
+// returns. This lets a command reuse the completion logic it already wrote
+// for bash/zsh/pwsh instead of duplicating it in DynamicSuggestionsFunc.
+//
+// ok is false when toComplete isn't a flag value and command has no
+// ValidArgsFunction, in which case the caller should fall back to the
+// regular flag/subcommand suggestions. When toComplete is a flag value, ok
+// is always true; if that flag has no registered completion func, no
+// suggestions are returned.
+func shellCompSuggestions(command *cobra.Command, cmdArgs []string, toComplete string) (suggestions []prompt.Suggest, directive cobra.ShellCompDirective, isFlagValue bool, ok bool) {
+	args, flagName, valuePrefix, isFlagValue := splitFlagValueArg(command, cmdArgs, toComplete)
+
+	completionFunc := command.ValidArgsFunction
+	if isFlagValue {
+		f, found := command.GetFlagCompletionFunc(flagName)
+		if !found {
+			return nil, cobra.ShellCompDirectiveNoFileComp, true, true
+		}
+		completionFunc = f
+	}
+
+	if completionFunc == nil {
+		return nil, cobra.ShellCompDirectiveDefault, isFlagValue, false
+	}
+
+	results, directive := completionFunc(command, args, valuePrefix)
+	if directive&cobra.ShellCompDirectiveError != 0 {
+		return nil, directive, isFlagValue, true
+	}
+
+	switch {
+	case directive&cobra.ShellCompDirectiveFilterFileExt != 0:
+		suggestions = completeFilesWithExt(valuePrefix, results)
+	case directive&cobra.ShellCompDirectiveFilterDirs != 0:
+		suggestions = completeDirs(valuePrefix, results)
+	default:
+		suggestions = toSuggestions(results)
+	}
+
+	// ShellCompDirectiveNoFileComp has nothing to suppress here: unlike a
+	// real shell, the prompt never falls back to listing filesystem names
+	// on its own. ShellCompDirectiveNoSpace likewise needs no handling:
+	// go-prompt's completer replaces the word under the cursor with
+	// Suggest.Text and never appends a trailing space of its own, so a
+	// selected suggestion is already "no space" by construction.
+	if directive&cobra.ShellCompDirectiveKeepOrder == 0 {
+		sort.Slice(suggestions, func(i, j int) bool { return suggestions[i].Text < suggestions[j].Text })
+	}
+
+	return prompt.FilterHasPrefix(suggestions, valuePrefix, true), directive, isFlagValue, true
+}
+
+// splitFlagValueArg decides whether toComplete is the value of a preceding
+// flag (either "--format j<TAB>" or the combined "--format=j<TAB>"). args is
+// the positional-only argument list a completion func should see; flagName
+// and valuePrefix are only meaningful when isFlagValue is true. A bare
+// "-f"/"--format" preceding token is only treated as a flag-value position
+// when that flag actually takes a value (NoOptDefVal == ""); boolean and
+// other no-value flags fall through to normal suggestions instead.
+func splitFlagValueArg(command *cobra.Command, cmdArgs []string, toComplete string) (args []string, flagName string, valuePrefix string, isFlagValue bool) {
+	args = cmdArgs
+	if len(args) > 0 && toComplete != "" && args[len(args)-1] == toComplete {
+		args = args[:len(args)-1]
+	}
+
+	if strings.HasPrefix(toComplete, "--") {
+		if idx := strings.IndexByte(toComplete, '='); idx >= 0 {
+			return stripFlags(args), toComplete[2:idx], toComplete[idx+1:], true
+		}
+		return stripFlags(args), "", toComplete, false
+	}
+
+	if len(args) > 0 {
+		last := args[len(args)-1]
+		if strings.HasPrefix(last, "-") && !strings.Contains(last, "=") {
+			name := strings.TrimLeft(last, "-")
+			if flag := lookupFlag(command, name); flag != nil && flag.NoOptDefVal == "" {
+				// GetFlagCompletionFunc is keyed by long name, so resolve a
+				// shorthand (-f) to it before returning.
+				return stripFlags(args[:len(args)-1]), flag.Name, toComplete, true
+			}
+		}
+	}
+
+	return stripFlags(args), "", toComplete, false
+}
+
+// lookupFlag resolves a preceding token's flag name (long or shorthand,
+// already stripped of leading "-"/"--") against command's local and
+// inherited flags.
+func lookupFlag(command *cobra.Command, name string) *pflag.Flag {
+	if command == nil {
+		return nil
+	}
+	if f := command.Flags().Lookup(name); f != nil {
+		return f
+	}
+	if f := command.InheritedFlags().Lookup(name); f != nil {
+		return f
+	}
+	if len(name) == 1 {
+		if f := command.Flags().ShorthandLookup(name); f != nil {
+			return f
+		}
+		if f := command.InheritedFlags().ShorthandLookup(name); f != nil {
+			return f
+		}
+	}
+	return nil
+}
+
+// stripFlags removes flag tokens so only positional arguments remain,
+// mirroring what Cobra passes to a ValidArgsFunction.
+func stripFlags(args []string) []string {
+	positional := make([]string, 0, len(args))
+	for _, a := range args {
+		if !strings.HasPrefix(a, "-") {
+			positional = append(positional, a)
+		}
+	}
+	return positional
+}
+
+// toSuggestions turns raw completion strings into prompt.Suggest entries,
+// splitting out a "\t"-separated description as cobra's own shell scripts
+// do.
+func toSuggestions(results []string) []prompt.Suggest {
+	suggestions := make([]prompt.Suggest, 0, len(results))
+	for _, r := range results {
+		text, description := r, ""
+		if idx := strings.IndexByte(r, '\t'); idx >= 0 {
+			text, description = r[:idx], strings.TrimSpace(r[idx+1:])
+		}
+		suggestions = append(suggestions, prompt.Suggest{Text: text, Description: description})
+	}
+	return suggestions
+}
+
+// completeFilesWithExt lists files in the current directory whose extension
+// is one of exts (given with or without the leading "."), for
+// ShellCompDirectiveFilterFileExt completion funcs. toComplete is not used
+// here; shellCompSuggestions filters the result by prefix afterwards.
+func completeFilesWithExt(toComplete string, exts []string) []prompt.Suggest {
+	entries, err := os.ReadDir(".")
+	if err != nil {
+		return nil
+	}
+
+	var suggestions []prompt.Suggest
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		for _, ext := range exts {
+			if strings.HasSuffix(entry.Name(), "."+strings.TrimPrefix(ext, ".")) {
+				suggestions = append(suggestions, prompt.Suggest{Text: entry.Name()})
+				break
+			}
+		}
+	}
+	return suggestions
+}
+
+// completeDirs lists subdirectories under roots[0] (or the current
+// directory when roots is empty), for ShellCompDirectiveFilterDirs
+// completion funcs.
+func completeDirs(toComplete string, roots []string) []prompt.Suggest {
+	root := "."
+	if len(roots) > 0 && roots[0] != "" {
+		root = roots[0]
+	}
+
+	entries, err := os.ReadDir(root)
+	if err != nil {
+		return nil
+	}
+
+	var suggestions []prompt.Suggest
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		suggestions = append(suggestions, prompt.Suggest{Text: filepath.Join(root, entry.Name()) + string(os.PathSeparator)})
+	}
+	return suggestions
+}
@@ -0,0 +1,128 @@
+package cobraprompt
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+	"github.com/vikasrao23/go-prompt"
+)
+
+func TestSplitFlagValueArg(t *testing.T) {
+	newCmd := func() *cobra.Command {
+		cmd := &cobra.Command{Use: "root"}
+		cmd.Flags().StringP("format", "f", "", "output format")
+		cmd.Flags().BoolP("verbose", "v", false, "verbose output")
+		return cmd
+	}
+
+	tests := []struct {
+		name            string
+		cmdArgs         []string
+		toComplete      string
+		wantArgs        []string
+		wantFlagName    string
+		wantValuePrefix string
+		wantIsFlagValue bool
+	}{
+		{
+			name:            "combined long flag",
+			cmdArgs:         []string{"--format=j"},
+			toComplete:      "--format=j",
+			wantArgs:        []string{},
+			wantFlagName:    "format",
+			wantValuePrefix: "j",
+			wantIsFlagValue: true,
+		},
+		{
+			name:            "separate long flag value",
+			cmdArgs:         []string{"--format", "j"},
+			toComplete:      "j",
+			wantArgs:        []string{},
+			wantFlagName:    "format",
+			wantValuePrefix: "j",
+			wantIsFlagValue: true,
+		},
+		{
+			name:            "shorthand resolves to long name",
+			cmdArgs:         []string{"-f", "j"},
+			toComplete:      "j",
+			wantArgs:        []string{},
+			wantFlagName:    "format",
+			wantValuePrefix: "j",
+			wantIsFlagValue: true,
+		},
+		{
+			name:            "boolean flag is not a value position",
+			cmdArgs:         []string{"--verbose"},
+			toComplete:      "",
+			wantArgs:        []string{},
+			wantFlagName:    "",
+			wantValuePrefix: "",
+			wantIsFlagValue: false,
+		},
+		{
+			name:            "unknown flag is not a value position",
+			cmdArgs:         []string{"--bogus"},
+			toComplete:      "",
+			wantArgs:        []string{},
+			wantFlagName:    "",
+			wantValuePrefix: "",
+			wantIsFlagValue: false,
+		},
+		{
+			name:            "positional argument",
+			cmdArgs:         []string{"arg1"},
+			toComplete:      "arg1",
+			wantArgs:        []string{},
+			wantFlagName:    "",
+			wantValuePrefix: "arg1",
+			wantIsFlagValue: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			args, flagName, valuePrefix, isFlagValue := splitFlagValueArg(newCmd(), tt.cmdArgs, tt.toComplete)
+			if !reflect.DeepEqual(args, tt.wantArgs) {
+				t.Errorf("args = %v, want %v", args, tt.wantArgs)
+			}
+			if flagName != tt.wantFlagName {
+				t.Errorf("flagName = %q, want %q", flagName, tt.wantFlagName)
+			}
+			if valuePrefix != tt.wantValuePrefix {
+				t.Errorf("valuePrefix = %q, want %q", valuePrefix, tt.wantValuePrefix)
+			}
+			if isFlagValue != tt.wantIsFlagValue {
+				t.Errorf("isFlagValue = %v, want %v", isFlagValue, tt.wantIsFlagValue)
+			}
+		})
+	}
+}
+
+func TestToSuggestions(t *testing.T) {
+	results := []string{"foo\tthe foo thing", "bar"}
+	got := toSuggestions(results)
+	want := []prompt.Suggest{
+		{Text: "foo", Description: "the foo thing"},
+		{Text: "bar", Description: ""},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("toSuggestions(%v) = %v, want %v", results, got, want)
+	}
+}
+
+func TestLookupFlag(t *testing.T) {
+	cmd := &cobra.Command{Use: "root"}
+	cmd.Flags().StringP("format", "f", "", "output format")
+
+	if got := lookupFlag(cmd, "format"); got == nil || got.Name != "format" {
+		t.Errorf("lookupFlag(long name) = %v, want format flag", got)
+	}
+	if got := lookupFlag(cmd, "f"); got == nil || got.Name != "format" {
+		t.Errorf("lookupFlag(shorthand) = %v, want format flag", got)
+	}
+	if got := lookupFlag(cmd, "bogus"); got != nil {
+		t.Errorf("lookupFlag(unknown) = %v, want nil", got)
+	}
+}